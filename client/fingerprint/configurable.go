@@ -0,0 +1,14 @@
+package fingerprint
+
+// Configurable is an optional interface a Fingerprint implementation can
+// satisfy to receive operator-supplied arguments (the `fingerprint { args =
+// {...} }` config block) without changing NewFingerprint's signature.
+// FingerprintManager calls SetArgs once, immediately after construction and
+// before the first Fingerprint call, if the fingerprinter implements this
+// interface and at least one arg is configured for it; with no args
+// configured, SetArgs is not called.
+type Configurable interface {
+	// SetArgs receives the operator-supplied args for this fingerprinter.
+	// It is called at most once, before Fingerprint is ever called.
+	SetArgs(args map[string]string)
+}
@@ -0,0 +1,15 @@
+package fingerprint
+
+// Subscribable is an optional interface a Fingerprint implementation can
+// satisfy to get fingerprinted immediately when the underlying hardware or
+// service it watches changes, rather than waiting up to Periodic's period.
+// The returned channel is read for the lifetime of the fingerprinter; a send
+// on it (an empty struct{}{} is enough) tells the FingerprintManager to
+// invalidate and immediately re-run Fingerprint.
+type Subscribable interface {
+	// Subscribe returns a channel that the FingerprintManager selects on
+	// alongside its periodic ticker. Implementations are expected to own
+	// whatever OS-level watch (socket, udev, etc.) feeds the channel and to
+	// stop feeding it once the process exits.
+	Subscribe() <-chan struct{}
+}
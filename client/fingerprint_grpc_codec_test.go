@@ -0,0 +1,23 @@
+package client
+
+import "testing"
+
+func TestGobCodec_RoundTrips(t *testing.T) {
+	var codec gobCodec
+
+	in := &fingerprintPeriodicResponse{Periodic: true, Period: 42}
+
+	data, err := codec.Marshal(in)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var out fingerprintPeriodicResponse
+	if err := codec.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if out != *in {
+		t.Fatalf("round-trip mismatch: got %+v, want %+v", out, *in)
+	}
+}
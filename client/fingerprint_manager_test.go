@@ -0,0 +1,66 @@
+package client
+
+import "testing"
+
+// TestInvalidate_NamespacedKeys ensures a fingerprinter and a driver that
+// share a bare name (e.g. an external fingerprint plugin named "docker"
+// colliding with the built-in docker driver) get independent invalidation
+// channels, and that Invalidate signals both without one unregistering the
+// other.
+func TestInvalidate_NamespacedKeys(t *testing.T) {
+	fm := &FingerprintManager{
+		invalidateCh: make(map[string]chan struct{}),
+	}
+
+	fingerprintCh := make(chan struct{}, 1)
+	driverCh := make(chan struct{}, 1)
+	fm.invalidateCh[invalidateFingerprintKey("docker")] = fingerprintCh
+	fm.invalidateCh[invalidateDriverKey("docker")] = driverCh
+
+	fm.Invalidate("docker")
+
+	select {
+	case <-fingerprintCh:
+	default:
+		t.Fatal("expected the fingerprinter's channel to be signaled")
+	}
+
+	select {
+	case <-driverCh:
+	default:
+		t.Fatal("expected the driver's channel to be signaled")
+	}
+
+	// Unregistering the driver's entry, as watchDriver does on exit, must not
+	// affect the fingerprinter's still-live entry keyed under a different
+	// namespace.
+	delete(fm.invalidateCh, invalidateDriverKey("docker"))
+	if _, ok := fm.invalidateCh[invalidateFingerprintKey("docker")]; !ok {
+		t.Fatal("deleting the driver's key should not remove the fingerprinter's key")
+	}
+}
+
+func TestInvalidate_UnknownNameIsNoop(t *testing.T) {
+	fm := &FingerprintManager{
+		invalidateCh: make(map[string]chan struct{}),
+	}
+
+	// Must not panic or block when nothing is registered under either
+	// namespace.
+	fm.Invalidate("does-not-exist")
+}
+
+func TestIsPluginFingerprint(t *testing.T) {
+	fm := &FingerprintManager{
+		plugins: map[string]*loadedPlugin{
+			"gpu": {name: "gpu"},
+		},
+	}
+
+	if !fm.isPluginFingerprint("gpu") {
+		t.Fatal("expected gpu to be reported as plugin-backed")
+	}
+	if fm.isPluginFingerprint("cpu") {
+		t.Fatal("did not expect cpu to be reported as plugin-backed")
+	}
+}
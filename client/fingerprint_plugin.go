@@ -0,0 +1,534 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hashicorp/nomad/client/fingerprint"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+)
+
+// HandshakeConfig is used to ensure that external fingerprinter plugins are
+// speaking the same protocol that this version of Nomad expects. Bumping
+// ProtocolVersion is a breaking change for every external fingerprinter
+// binary already deployed to a client's plugin directory.
+var HandshakeConfig = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "NOMAD_FINGERPRINT_PLUGIN",
+	MagicCookieValue: "hashicorp",
+}
+
+// fingerprintServiceName is the gRPC service name advertised by
+// fingerprintGRPCServiceDesc. It doesn't need to match anything outside this
+// file; it only needs to be stable, since it's baked into the method paths
+// both fingerprintGRPCClient and the hand-written handlers in
+// fingerprintGRPCServiceDesc use.
+const fingerprintServiceName = "nomad.client.fingerprint.Fingerprint"
+
+// fingerprintPluginMap is passed to go-plugin on both ends of the pipe so it
+// knows which plugin implementations to dispense.
+var fingerprintPluginMap = map[string]plugin.Plugin{
+	"fingerprint": &FingerprintPlugin{},
+}
+
+// FingerprintPlugin is the go-plugin glue that lets an out-of-process
+// fingerprinter satisfy fingerprint.Fingerprint (and, optionally,
+// fingerprint.HealthCheck and fingerprint.Subscribable) across the plugin
+// boundary. Nomad links against it to dispense a client-side stub; external
+// plugin binaries link against it (via ServeFingerprintPlugin) with Impl set
+// to serve their own fingerprint.Fingerprint.
+//
+// This is a gRPC-only plugin: it implements plugin.GRPCPlugin, and
+// plugin.NetRPCUnsupportedPlugin satisfies the legacy plugin.Plugin interface
+// go-plugin still requires by failing loudly if net/rpc is ever negotiated
+// instead. launchFingerprintPlugin sets AllowedProtocols to
+// []plugin.Protocol{plugin.ProtocolGRPC} so that negotiation always picks
+// gRPC.
+//
+// Because the transport is a real gRPC stream rather than net/rpc, an
+// external fingerprinter can implement fingerprint.Subscribable and have it
+// actually take effect: fingerprintGRPCClient.Subscribe opens a
+// server-streaming RPC that the plugin's Subscribe channel feeds, so a
+// hot-swap fingerprinter (GPU, FPGA, license server) gets the same
+// invalidate-on-change behavior as a built-in one instead of being
+// permanently poll-only.
+type FingerprintPlugin struct {
+	plugin.NetRPCUnsupportedPlugin
+
+	Impl fingerprint.Fingerprint
+}
+
+// GRPCServer registers the fingerprint service against the go-plugin-managed
+// gRPC server running inside the external plugin process.
+func (p *FingerprintPlugin) GRPCServer(_ *plugin.GRPCBroker, s *grpc.Server) error {
+	s.RegisterService(&fingerprintGRPCServiceDesc, &fingerprintGRPCServer{impl: p.Impl})
+	return nil
+}
+
+// GRPCClient dispenses the client-side stub the Nomad client process uses,
+// backed by the gRPC connection go-plugin has already dialed to the
+// external plugin process.
+func (p *FingerprintPlugin) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, conn *grpc.ClientConn) (interface{}, error) {
+	return &fingerprintGRPCClient{conn: conn}, nil
+}
+
+// fingerprintPeriodicResponse carries the two return values of
+// fingerprint.Fingerprint.Periodic across the RPC boundary.
+type fingerprintPeriodicResponse struct {
+	Periodic bool
+	Period   time.Duration
+}
+
+// fingerprintEmptyRequest is used for RPCs that take no arguments; gob needs
+// a concrete addressable type to decode into, so this stands in for the
+// "no request" case instead of an empty interface.
+type fingerprintEmptyRequest struct{}
+
+// fingerprintSubscribeEvent is sent on the Subscribe stream once per change
+// notification. It carries no data: the mere arrival of a message is the
+// signal, mirroring the struct{} sent on a fingerprint.Subscribable channel.
+type fingerprintSubscribeEvent struct{}
+
+// gobCodec adapts encoding/gob to grpc's Codec interface so the fingerprint
+// service can exchange the same plain Go structs (cstructs.FingerprintRequest
+// and friends) that the rest of FingerprintManager already uses, without
+// requiring plugin authors to depend on protocol buffers. Both
+// launchFingerprintPlugin (client dial options) and ServeFingerprintPlugin
+// (server options) install this codec, so it must match on both ends of the
+// pipe.
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (gobCodec) String() string { return "gob" }
+
+// fingerprintGRPCServerIface is the method set fingerprintGRPCServiceDesc
+// dispatches onto. grpc.Server.RegisterService checks at registration time
+// that the registered implementation satisfies this interface.
+type fingerprintGRPCServerIface interface {
+	Fingerprint(context.Context, *cstructs.FingerprintRequest) (*cstructs.FingerprintResponse, error)
+	Periodic(context.Context, *fingerprintEmptyRequest) (*fingerprintPeriodicResponse, error)
+	GetHealthCheckInterval(context.Context, *cstructs.HealthCheckIntervalRequest) (*cstructs.HealthCheckIntervalResponse, error)
+	HealthCheck(context.Context, *cstructs.HealthCheckRequest) (*cstructs.HealthCheckResponse, error)
+	Subscribe(*fingerprintEmptyRequest, fingerprintSubscribeServer) error
+}
+
+// fingerprintSubscribeServer is the server side of the Subscribe streaming
+// RPC, matching the shape protoc-gen-go would generate for a
+// server-streaming method.
+type fingerprintSubscribeServer interface {
+	Send(*fingerprintSubscribeEvent) error
+	grpc.ServerStream
+}
+
+type fingerprintSubscribeServerStream struct {
+	grpc.ServerStream
+}
+
+func (x *fingerprintSubscribeServerStream) Send(m *fingerprintSubscribeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// fingerprintGRPCServer only ever runs inside the external plugin binary
+// (via ServeFingerprintPlugin), adapting gRPC calls onto the plugin author's
+// fingerprint.Fingerprint implementation.
+type fingerprintGRPCServer struct {
+	impl fingerprint.Fingerprint
+}
+
+func (s *fingerprintGRPCServer) Fingerprint(_ context.Context, req *cstructs.FingerprintRequest) (*cstructs.FingerprintResponse, error) {
+	resp := &cstructs.FingerprintResponse{}
+	if err := s.impl.Fingerprint(req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *fingerprintGRPCServer) Periodic(context.Context, *fingerprintEmptyRequest) (*fingerprintPeriodicResponse, error) {
+	periodic, period := s.impl.Periodic()
+	return &fingerprintPeriodicResponse{Periodic: periodic, Period: period}, nil
+}
+
+func (s *fingerprintGRPCServer) GetHealthCheckInterval(_ context.Context, req *cstructs.HealthCheckIntervalRequest) (*cstructs.HealthCheckIntervalResponse, error) {
+	hc, ok := s.impl.(fingerprint.HealthCheck)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "fingerprinter does not support health checking")
+	}
+	resp := &cstructs.HealthCheckIntervalResponse{}
+	if err := hc.GetHealthCheckInterval(req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *fingerprintGRPCServer) HealthCheck(_ context.Context, req *cstructs.HealthCheckRequest) (*cstructs.HealthCheckResponse, error) {
+	hc, ok := s.impl.(fingerprint.HealthCheck)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "fingerprinter does not support health checking")
+	}
+	resp := &cstructs.HealthCheckResponse{}
+	if err := hc.HealthCheck(req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Subscribe streams a fingerprintSubscribeEvent to the host every time the
+// wrapped fingerprint.Subscribable fires. If impl doesn't implement
+// fingerprint.Subscribable, it fails the stream with codes.Unimplemented so
+// fingerprintGRPCClient.Subscribe can tell "not supported" apart from "no
+// events yet" and simply never signal its channel, the same as a built-in,
+// non-Subscribable fingerprinter.
+func (s *fingerprintGRPCServer) Subscribe(_ *fingerprintEmptyRequest, stream fingerprintSubscribeServer) error {
+	sub, ok := s.impl.(fingerprint.Subscribable)
+	if !ok {
+		return status.Error(codes.Unimplemented, "fingerprinter does not support subscriptions")
+	}
+
+	ch := sub.Subscribe()
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(&fingerprintSubscribeEvent{}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func fingerprintFingerprintHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(cstructs.FingerprintRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(fingerprintGRPCServerIface).Fingerprint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + fingerprintServiceName + "/Fingerprint"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(fingerprintGRPCServerIface).Fingerprint(ctx, req.(*cstructs.FingerprintRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func fingerprintPeriodicHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(fingerprintEmptyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(fingerprintGRPCServerIface).Periodic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + fingerprintServiceName + "/Periodic"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(fingerprintGRPCServerIface).Periodic(ctx, req.(*fingerprintEmptyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func fingerprintGetHealthCheckIntervalHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(cstructs.HealthCheckIntervalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(fingerprintGRPCServerIface).GetHealthCheckInterval(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + fingerprintServiceName + "/GetHealthCheckInterval"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(fingerprintGRPCServerIface).GetHealthCheckInterval(ctx, req.(*cstructs.HealthCheckIntervalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func fingerprintHealthCheckHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(cstructs.HealthCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(fingerprintGRPCServerIface).HealthCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + fingerprintServiceName + "/HealthCheck"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(fingerprintGRPCServerIface).HealthCheck(ctx, req.(*cstructs.HealthCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func fingerprintSubscribeHandler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(fingerprintEmptyRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(fingerprintGRPCServerIface).Subscribe(m, &fingerprintSubscribeServerStream{stream})
+}
+
+// fingerprintGRPCServiceDesc describes the fingerprint gRPC service by hand,
+// the way protoc-gen-go would generate it from a .proto file. Hand-rolling
+// it avoids requiring plugin authors to take a protobuf dependency just to
+// exchange the same plain Go structs the rest of FingerprintManager already
+// uses (see gobCodec).
+var fingerprintGRPCServiceDesc = grpc.ServiceDesc{
+	ServiceName: fingerprintServiceName,
+	HandlerType: (*fingerprintGRPCServerIface)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Fingerprint", Handler: fingerprintFingerprintHandler},
+		{MethodName: "Periodic", Handler: fingerprintPeriodicHandler},
+		{MethodName: "GetHealthCheckInterval", Handler: fingerprintGetHealthCheckIntervalHandler},
+		{MethodName: "HealthCheck", Handler: fingerprintHealthCheckHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       fingerprintSubscribeHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "fingerprint_plugin.go",
+}
+
+// fingerprintGRPCClient runs in the Nomad client process and forwards calls
+// to the external plugin binary over the gRPC connection go-plugin dialed
+// for us. It implements fingerprint.Fingerprint, fingerprint.HealthCheck,
+// and fingerprint.Subscribable so the rest of FingerprintManager can treat a
+// loaded plugin exactly like a built-in.
+type fingerprintGRPCClient struct {
+	conn *grpc.ClientConn
+}
+
+func (c *fingerprintGRPCClient) Fingerprint(req *cstructs.FingerprintRequest, resp *cstructs.FingerprintResponse) error {
+	return c.conn.Invoke(context.Background(), "/"+fingerprintServiceName+"/Fingerprint", req, resp)
+}
+
+func (c *fingerprintGRPCClient) Periodic() (bool, time.Duration) {
+	var resp fingerprintPeriodicResponse
+	if err := c.conn.Invoke(context.Background(), "/"+fingerprintServiceName+"/Periodic", &fingerprintEmptyRequest{}, &resp); err != nil {
+		// A plugin that can't answer this call is treated as non-periodic
+		// rather than aborting fingerprinting for every other module.
+		return false, 0
+	}
+	return resp.Periodic, resp.Period
+}
+
+func (c *fingerprintGRPCClient) GetHealthCheckInterval(req *cstructs.HealthCheckIntervalRequest, resp *cstructs.HealthCheckIntervalResponse) error {
+	return c.conn.Invoke(context.Background(), "/"+fingerprintServiceName+"/GetHealthCheckInterval", req, resp)
+}
+
+func (c *fingerprintGRPCClient) HealthCheck(req *cstructs.HealthCheckRequest, resp *cstructs.HealthCheckResponse) error {
+	return c.conn.Invoke(context.Background(), "/"+fingerprintServiceName+"/HealthCheck", req, resp)
+}
+
+// Subscribe opens a server-streaming RPC and forwards each event the plugin
+// sends onto the returned channel. If the plugin's impl doesn't implement
+// fingerprint.Subscribable, the server fails the stream with
+// codes.Unimplemented; the goroutine below simply exits and the channel is
+// never signaled, which FingerprintManager's select treats identically to a
+// non-Subscribable fingerprinter.
+func (c *fingerprintGRPCClient) Subscribe() <-chan struct{} {
+	ch := make(chan struct{}, 1)
+
+	go func() {
+		stream, err := c.conn.NewStream(context.Background(), &grpc.StreamDesc{
+			StreamName:    "Subscribe",
+			ServerStreams: true,
+		}, "/"+fingerprintServiceName+"/Subscribe")
+		if err != nil {
+			return
+		}
+		if err := stream.SendMsg(&fingerprintEmptyRequest{}); err != nil {
+			return
+		}
+		if err := stream.CloseSend(); err != nil {
+			return
+		}
+
+		for {
+			event := new(fingerprintSubscribeEvent)
+			if err := stream.RecvMsg(event); err != nil {
+				// io.EOF (plugin closed the stream) or codes.Unimplemented
+				// (plugin isn't Subscribable): either way, stop forwarding.
+				return
+			}
+			select {
+			case ch <- struct{}{}:
+			default:
+				// A re-fingerprint is already pending; no need to queue
+				// another.
+			}
+		}
+	}()
+
+	return ch
+}
+
+// ServeFingerprintPlugin runs the calling process as an external
+// fingerprinter plugin, serving impl over the gRPC transport
+// FingerprintPlugin expects. An external fingerprinter binary calls this
+// from its own main():
+//
+//	func main() {
+//		client.ServeFingerprintPlugin(myFingerprinter{})
+//	}
+func ServeFingerprintPlugin(impl fingerprint.Fingerprint) {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: HandshakeConfig,
+		Plugins: map[string]plugin.Plugin{
+			"fingerprint": &FingerprintPlugin{Impl: impl},
+		},
+		GRPCServer: func(opts []grpc.ServerOption) *grpc.Server {
+			return grpc.NewServer(append(opts, grpc.CustomCodec(gobCodec{}))...)
+		},
+	})
+}
+
+// loadedPlugin tracks a running external fingerprinter so it can be
+// restarted if it crashes and cleanly killed on shutdown.
+type loadedPlugin struct {
+	name   string
+	path   string
+	client *plugin.Client
+	fp     fingerprint.Fingerprint
+}
+
+// loadExternalFingerprints launches every non-directory entry in pluginDir
+// as a go-plugin client speaking the fingerprint contract. Entries are not
+// checked for being executable; one that fails to launch is logged and
+// skipped.
+// The returned map's keys behave exactly like fingerprint.BuiltinFingerprints()
+// entries: the whitelist/blacklist logic in Run applies to them uniformly.
+func (fm *FingerprintManager) loadExternalFingerprints(pluginDir string) (map[string]fingerprint.Fingerprint, error) {
+	if pluginDir == "" {
+		return nil, nil
+	}
+
+	entries, err := ioutil.ReadDir(pluginDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read fingerprint plugin_dir %q: %v", pluginDir, err)
+	}
+
+	loaded := make(map[string]fingerprint.Fingerprint, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(pluginDir, entry.Name())
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+
+		lp, err := fm.launchFingerprintPlugin(name, path)
+		if err != nil {
+			fm.logger.Printf("[ERR] client.fingerprint_manager: failed to load fingerprint plugin %q: %v", path, err)
+			continue
+		}
+
+		fm.pluginLock.Lock()
+		fm.plugins[name] = lp
+		fm.pluginLock.Unlock()
+
+		loaded[name] = lp.fp
+	}
+
+	return loaded, nil
+}
+
+// launchFingerprintPlugin spawns a single external fingerprinter binary and
+// dispenses its gRPC client. The returned loadedPlugin is later used by
+// restartFingerprintPlugin to recover from a crash.
+func (fm *FingerprintManager) launchFingerprintPlugin(name, path string) (*loadedPlugin, error) {
+	client := plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  HandshakeConfig,
+		Plugins:          fingerprintPluginMap,
+		Cmd:              exec.Command(path),
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+		GRPCDialOptions:  []grpc.DialOption{grpc.WithCodec(gobCodec{})},
+	})
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return nil, err
+	}
+
+	raw, err := rpcClient.Dispense("fingerprint")
+	if err != nil {
+		client.Kill()
+		return nil, err
+	}
+
+	fp, ok := raw.(fingerprint.Fingerprint)
+	if !ok {
+		client.Kill()
+		return nil, fmt.Errorf("plugin %q does not implement fingerprint.Fingerprint", name)
+	}
+
+	return &loadedPlugin{name: name, path: path, client: client, fp: fp}, nil
+}
+
+// restartFingerprintPlugin is invoked by runFingerprint when a plugin-backed
+// fingerprinter's RPC call fails, which go-plugin surfaces as a connection
+// error whenever the external process has crashed.
+func (fm *FingerprintManager) restartFingerprintPlugin(name string) (fingerprint.Fingerprint, error) {
+	fm.pluginLock.Lock()
+	lp, ok := fm.plugins[name]
+	fm.pluginLock.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no plugin registered for fingerprinter %q", name)
+	}
+
+	lp.client.Kill()
+
+	newLp, err := fm.launchFingerprintPlugin(lp.name, lp.path)
+	if err != nil {
+		return nil, err
+	}
+
+	fm.pluginLock.Lock()
+	fm.plugins[name] = newLp
+	fm.pluginLock.Unlock()
+
+	return newLp.fp, nil
+}
+
+// killFingerprintPlugins terminates every external fingerprinter process.
+// It is called when Run's shutdownCh fires.
+func (fm *FingerprintManager) killFingerprintPlugins() {
+	fm.pluginLock.Lock()
+	defer fm.pluginLock.Unlock()
+
+	for name, lp := range fm.plugins {
+		lp.client.Kill()
+		delete(fm.plugins, name)
+	}
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"testing"
+)
+
+func testFingerprintManager(t *testing.T) *FingerprintManager {
+	return &FingerprintManager{
+		logger:  log.New(os.Stderr, "", log.LstdFlags),
+		plugins: make(map[string]*loadedPlugin),
+	}
+}
+
+func TestLoadExternalFingerprints_NoPluginDir(t *testing.T) {
+	fm := testFingerprintManager(t)
+
+	loaded, err := fm.loadExternalFingerprints("")
+	if err != nil {
+		t.Fatalf("expected no error for an unset plugin_dir, got %v", err)
+	}
+	if loaded != nil {
+		t.Fatalf("expected no plugins to be loaded, got %v", loaded)
+	}
+}
+
+func TestLoadExternalFingerprints_MissingPluginDir(t *testing.T) {
+	fm := testFingerprintManager(t)
+
+	loaded, err := fm.loadExternalFingerprints("/path/does/not/exist")
+	if err != nil {
+		t.Fatalf("a missing plugin_dir should be treated as empty, got error %v", err)
+	}
+	if loaded != nil {
+		t.Fatalf("expected no plugins to be loaded, got %v", loaded)
+	}
+}
+
+func TestLoadExternalFingerprints_EmptyPluginDir(t *testing.T) {
+	fm := testFingerprintManager(t)
+
+	dir, err := ioutil.TempDir("", "nomad-fingerprint-plugins")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	loaded, err := fm.loadExternalFingerprints(dir)
+	if err != nil {
+		t.Fatalf("expected no error for an empty plugin_dir, got %v", err)
+	}
+	if len(loaded) != 0 {
+		t.Fatalf("expected no plugins to be loaded from an empty dir, got %v", loaded)
+	}
+}
+
+func TestKillFingerprintPlugins_NoPlugins(t *testing.T) {
+	fm := testFingerprintManager(t)
+
+	// Must not panic when there is nothing to kill.
+	fm.killFingerprintPlugins()
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/client/config"
+	cstructs "github.com/hashicorp/nomad/client/structs"
+)
+
+// slowFingerprint blocks for longer than any timeout passed to it, so tests
+// can exercise the timeout path deterministically.
+type slowFingerprint struct {
+	delay time.Duration
+}
+
+func (s *slowFingerprint) Fingerprint(*cstructs.FingerprintRequest, *cstructs.FingerprintResponse) error {
+	time.Sleep(s.delay)
+	return nil
+}
+
+func (s *slowFingerprint) Periodic() (bool, time.Duration) { return true, time.Hour }
+
+func TestCallFingerprintWithTimeout_ReturnsTimeoutError(t *testing.T) {
+	f := &slowFingerprint{delay: 50 * time.Millisecond}
+
+	err := callFingerprintWithTimeout("slow", f, nil, &cstructs.FingerprintResponse{}, 5*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if _, ok := err.(*fingerprintTimeoutError); !ok {
+		t.Fatalf("expected a *fingerprintTimeoutError, got %T: %v", err, err)
+	}
+}
+
+func TestCallFingerprintWithTimeout_ZeroTimeoutWaitsForever(t *testing.T) {
+	f := &slowFingerprint{delay: 5 * time.Millisecond}
+
+	if err := callFingerprintWithTimeout("slow", f, nil, &cstructs.FingerprintResponse{}, 0); err != nil {
+		t.Fatalf("expected no error with a zero timeout, got %v", err)
+	}
+}
+
+func TestFingerprintOverride_ParsesArgs(t *testing.T) {
+	cfg := &config.Config{
+		Options: map[string]string{
+			"fingerprint.gpu.enabled":     "true",
+			"fingerprint.gpu.period":      "10s",
+			"fingerprint.gpu.timeout":     "2s",
+			"fingerprint.gpu.args.vendor": "nvidia",
+			"fingerprint.gpu.args.mode":   "exclusive",
+			"fingerprint.cpu.args.vendor": "intel",
+		},
+	}
+	fm := &FingerprintManager{getConfig: func() *config.Config { return cfg }}
+
+	override := fm.fingerprintOverride("gpu")
+
+	if !override.enabled {
+		t.Fatal("expected gpu fingerprinter to be enabled")
+	}
+	if override.period != 10*time.Second {
+		t.Fatalf("expected a 10s period override, got %v", override.period)
+	}
+	if override.timeout != 2*time.Second {
+		t.Fatalf("expected a 2s timeout override, got %v", override.timeout)
+	}
+	if override.args["vendor"] != "nvidia" || override.args["mode"] != "exclusive" {
+		t.Fatalf("expected gpu args to be parsed, got %v", override.args)
+	}
+	if len(override.args) != 2 {
+		t.Fatalf("did not expect cpu's args to leak into gpu's override, got %v", override.args)
+	}
+}